@@ -4,6 +4,12 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+
+	"go.opentelemetry.io/otel/trace"
 )
 
 // ErrCommitFailed is returned when a participant fails to commit
@@ -14,36 +20,233 @@ type Params struct {
 	// LogFn is a function that can be used for logging errors. Leave empty for no logging
 	LogFn        func(msg string, args ...any)
 	Participants []Participant
+	// DecisionLog, if set, is written right after all participants have
+	// prepared (decision=commit) and again once all participants have
+	// committed (decision=done). A Recoverer uses it to resolve prepared
+	// transactions left behind by a coordinator crash. Leave empty to skip
+	// durable decision logging.
+	DecisionLog DecisionLog
+	// MaxParallelism bounds how many participants are prepared, or
+	// committed, concurrently. Leave zero to run all participants at once.
+	MaxParallelism int
+	// RetryPolicy, if set, re-runs a failed attempt when prepare fails with
+	// a transient error. It never retries once the commit phase has begun.
+	RetryPolicy RetryPolicy
+	// IsRetryable decides whether a prepare error is worth retrying under
+	// RetryPolicy. Leave empty to use DefaultIsRetryable.
+	IsRetryable func(error) bool
+	// Hooks, if set, is called around each participant's prepare, commit and
+	// rollback. Leave empty to skip.
+	Hooks Hooks
+	// Tracer, if set, opens a gosql2pc.transaction span for the attempt and
+	// a gosql2pc.prepare/gosql2pc.commit/gosql2pc.rollback child span per
+	// participant, tagged with its GID and index. Any go.opentelemetry.io/
+	// otel/trace.Tracer, e.g. otel.Tracer("gosql2pc"), satisfies this.
+	Tracer trace.Tracer
 }
 
-// Do runs the distributed transaction
+// Do runs the distributed transaction, retrying the whole attempt according
+// to params.RetryPolicy if prepare fails with a transient error.
 func Do(ctx context.Context, params Params) error {
 	log := getLog(params)
-	defer func() {
-		for i := range params.Participants {
-			if err := params.Participants[i].rollback(); err != nil {
-				log("rollback failed: %s", err)
+	isRetryable := params.IsRetryable
+	if isRetryable == nil {
+		isRetryable = DefaultIsRetryable
+	}
+
+	maxAttempts := params.RetryPolicy.maxAttempts()
+	var lastErr error
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		// each Participant implementation resets its own State at the start
+		// of Prepare, so re-running against the same participants gives
+		// every retry a fresh *sql.Tx and a new GID
+		err := doAttempt(ctx, params, params.Participants, log)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+
+		// a failure during or after commit is never safe to retry: some
+		// participants may already be committed
+		if errors.Is(err, ErrCommitFailed) {
+			return err
+		}
+		if attempt == maxAttempts || !isRetryable(err) {
+			return err
+		}
+		log("retrying after transient prepare error (attempt %d/%d): %s", attempt, maxAttempts, err)
+		if d := params.RetryPolicy.delay(attempt); d > 0 {
+			timer := time.NewTimer(d)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return ctx.Err()
+			case <-timer.C:
 			}
 		}
+	}
+	return lastErr
+}
+
+// doAttempt runs a single prepare/commit attempt against participants.
+func doAttempt(ctx context.Context, params Params, participants []Participant, log func(msg string, args ...any)) (err error) {
+	txid := newTxID()
+	gids := make([]string, len(participants))
+	for i := range participants {
+		gids[i] = gidFor(txid, i)
+	}
+
+	ctx, txSpan := startTransactionSpan(ctx, params.Tracer, txid)
+	defer func() { endSpan(txSpan, err) }()
+
+	// committedDecision becomes true once every participant has prepared and
+	// the commit decision has been durably recorded (or no DecisionLog is
+	// configured at all). Until then the decision is still reversible, so
+	// the finalizer below must roll back rather than retry-commit: a commit
+	// decision, once made, must never be reversed, so it must not be made
+	// before it is safely recorded.
+	var committedDecision bool
+	defer func() {
+		finalize(ctx, params, participants, gids, committedDecision, log)
 	}()
-	// prepare all participants
-	for i := range params.Participants {
-		if err := params.Participants[i].prepare(ctx); err != nil {
+
+	// prepare all participants concurrently; the first failure cancels the
+	// shared context so in-flight prepares on other participants abort quickly
+	pg, pctx := errgroup.WithContext(ctx)
+	if params.MaxParallelism > 0 {
+		pg.SetLimit(params.MaxParallelism)
+	}
+	for i := range participants {
+		i := i
+		pg.Go(func() error {
+			return prepareParticipant(pctx, params, participants[i], i, gids[i])
+		})
+	}
+	if err = pg.Wait(); err != nil {
+		return err
+	}
+
+	// the commit decision must be durable before we act on it: if it can't
+	// be recorded, finalize below must still roll back, not commit, since a
+	// later crash could otherwise lose the decision while some participants
+	// have already been told to commit
+	if params.DecisionLog != nil {
+		if lErr := params.DecisionLog.Record(txid, gids, DecisionCommit); lErr != nil {
+			err = fmt.Errorf("decision log record failed: %w", lErr)
 			return err
 		}
 	}
-	// commit all participants
-	for i := range params.Participants {
-		if err := params.Participants[i].commit(); err != nil {
-			log("commit failed: %s", err)
-			// since we have committed this participant, we need to rollback all other participants
-			// this may leave an inconsistent state
-			return fmt.Errorf("%w: %s", ErrCommitFailed, err.Error())
+	committedDecision = true
+
+	// commit all participants concurrently, but unlike prepare we must not
+	// abandon a commit because a sibling failed: every participant has
+	// already agreed to commit, so we run them all and join the errors
+	var (
+		cg         errgroup.Group
+		mu         sync.Mutex
+		commitErrs []error
+	)
+	if params.MaxParallelism > 0 {
+		cg.SetLimit(params.MaxParallelism)
+	}
+	for i := range participants {
+		i := i
+		cg.Go(func() error {
+			if cErr := commitParticipant(ctx, params, participants[i], i, gids[i]); cErr != nil {
+				log("commit failed: %s", cErr)
+				mu.Lock()
+				commitErrs = append(commitErrs, fmt.Errorf("participant %d: %w", i, cErr))
+				mu.Unlock()
+			}
+			return nil
+		})
+	}
+	_ = cg.Wait()
+	if len(commitErrs) > 0 {
+		// the commit decision has been made, so finalize retries the commit of
+		// every participant that failed rather than rolling it back; if those
+		// retries fail too, this may leave an inconsistent state
+		err = fmt.Errorf("%w: %s", ErrCommitFailed, errors.Join(commitErrs...))
+		return err
+	}
+
+	if params.DecisionLog != nil {
+		if lErr := params.DecisionLog.Record(txid, gids, DecisionDone); lErr != nil {
+			log("decision log record failed: %s", lErr)
 		}
 	}
 	return nil
 }
 
+func prepareParticipant(ctx context.Context, params Params, p Participant, index int, gid string) error {
+	spanCtx, span := startPhaseSpan(ctx, params.Tracer, "gosql2pc.prepare", index, gid, p.DBID())
+	params.Hooks.beforePrepare(index, gid)
+	start := time.Now()
+	err := p.Prepare(WithGID(spanCtx, gid))
+	params.Hooks.afterPrepare(index, gid, time.Since(start), err)
+	endSpan(span, err)
+	return err
+}
+
+func commitParticipant(ctx context.Context, params Params, p Participant, index int, gid string) error {
+	_, span := startPhaseSpan(ctx, params.Tracer, "gosql2pc.commit", index, gid, p.DBID())
+	params.Hooks.beforeCommit(index, gid)
+	start := time.Now()
+	err := p.Commit()
+	params.Hooks.afterCommit(index, gid, time.Since(start), err)
+	endSpan(span, err)
+	return err
+}
+
+func rollbackParticipant(ctx context.Context, params Params, p Participant, index int, gid string) error {
+	_, span := startPhaseSpan(ctx, params.Tracer, "gosql2pc.rollback", index, gid, p.DBID())
+	start := time.Now()
+	err := p.Rollback()
+	params.Hooks.onRollback(index, gid, time.Since(start), err)
+	endSpan(span, err)
+	return err
+}
+
+// finalizeCommitRetries bounds how many times finalize retries a commit for
+// a participant left in StatePrepared or StateCommitFailed once a commit
+// decision has been made.
+const finalizeCommitRetries = 3
+
+// finalize runs once an attempt is done and resolves any participant that
+// neither fully committed nor was cleanly rolled back during the attempt.
+// Only StatePrepared and StateCommitFailed participants need resolving;
+// StateInitial and StateCommitted ones are left untouched by Rollback
+// already. Once committedDecision is true every such participant is retried
+// to commit instead of rolled back: a commit decision, once made, must never
+// be reversed, since some participants may already carry it out.
+func finalize(ctx context.Context, params Params, participants []Participant, gids []string, committedDecision bool, log func(msg string, args ...any)) {
+	for i := range participants {
+		switch participants[i].State() {
+		case StatePrepared, StateCommitFailed:
+		default:
+			continue
+		}
+
+		if !committedDecision {
+			if err := rollbackParticipant(ctx, params, participants[i], i, gids[i]); err != nil {
+				log("rollback failed: %s", err)
+			}
+			continue
+		}
+
+		var err error
+		for attempt := 1; attempt <= finalizeCommitRetries; attempt++ {
+			if err = commitParticipant(ctx, params, participants[i], i, gids[i]); err == nil {
+				break
+			}
+			log("finalize: retrying commit for participant %d after a commit decision (attempt %d/%d): %s", i, attempt, finalizeCommitRetries, err)
+		}
+		if err != nil {
+			log("finalize: participant %d could not be committed after a commit decision: %s", i, err)
+		}
+	}
+}
+
 var defaultLog func(msg string, args ...any) = func(msg string, args ...any) {}
 var defaultPrefix = "gosql2pc-"
 
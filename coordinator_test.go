@@ -0,0 +1,131 @@
+package gosql2pc
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// mockParticipant is a minimal in-memory Participant used to exercise Do's
+// finalizer without a real database.
+type mockParticipant struct {
+	gid   string
+	state State
+
+	failCommit bool
+}
+
+func (m *mockParticipant) Prepare(ctx context.Context) error {
+	m.state = StateInitial
+	m.gid = GIDFromContext(ctx)
+	m.state = StatePrepared
+	return nil
+}
+
+func (m *mockParticipant) Commit() error {
+	m.state = StateCommitting
+	if m.failCommit {
+		m.state = StateCommitFailed
+		return errors.New("commit failed")
+	}
+	m.state = StateCommitted
+	return nil
+}
+
+func (m *mockParticipant) Rollback() error {
+	if m.state != StatePrepared && m.state != StateCommitFailed {
+		return nil
+	}
+	m.state = StateRolledBack
+	return nil
+}
+
+func (m *mockParticipant) ID() string {
+	return m.gid
+}
+
+func (m *mockParticipant) DBID() string {
+	return ""
+}
+
+func (m *mockParticipant) State() State {
+	return m.state
+}
+
+// TestDoNoRollbackAfterCommitDecision exercises the bug finalize fixes: once
+// every participant has prepared, a commit decision has been made, and a
+// later participant's Commit failing must never cause an earlier,
+// already-committed participant to be rolled back.
+func TestDoNoRollbackAfterCommitDecision(t *testing.T) {
+	const n = 5
+	const failAt = 2 // index of the participant whose Commit fails
+
+	participants := make([]*mockParticipant, n)
+	ifaceParticipants := make([]Participant, n)
+	for i := range participants {
+		participants[i] = &mockParticipant{failCommit: i == failAt}
+		ifaceParticipants[i] = participants[i]
+	}
+
+	err := Do(context.Background(), Params{Participants: ifaceParticipants})
+	if !errors.Is(err, ErrCommitFailed) {
+		t.Fatalf("expected ErrCommitFailed, got %v", err)
+	}
+
+	for i, p := range participants {
+		if p.State() == StateRolledBack {
+			t.Fatalf("participant %d was rolled back after a commit decision was recorded", i)
+		}
+		wantState := StateCommitted
+		if i == failAt {
+			wantState = StateCommitFailed
+		}
+		if p.State() != wantState {
+			t.Fatalf("participant %d: expected %s, got %s", i, wantState, p.State())
+		}
+	}
+}
+
+// failingDecisionLog always fails to record a commit decision, simulating a
+// disk full or fsync error.
+type failingDecisionLog struct{}
+
+func (failingDecisionLog) Record(txid string, participants []string, decision string) error {
+	return errors.New("disk full")
+}
+
+func (failingDecisionLog) Lookup(txid string) (string, error) {
+	return "", ErrDecisionNotFound
+}
+
+// TestDoRollsBackWhenDecisionLogRecordFails exercises the fix for committing
+// on an unrecorded decision: if the commit decision can't be durably
+// recorded, Do must not enter the commit phase at all, and every prepared
+// participant must be rolled back instead.
+func TestDoRollsBackWhenDecisionLogRecordFails(t *testing.T) {
+	const n = 3
+
+	participants := make([]*mockParticipant, n)
+	ifaceParticipants := make([]Participant, n)
+	for i := range participants {
+		participants[i] = &mockParticipant{}
+		ifaceParticipants[i] = participants[i]
+	}
+
+	err := Do(context.Background(), Params{
+		Participants: ifaceParticipants,
+		DecisionLog:  failingDecisionLog{},
+	})
+	if err == nil {
+		t.Fatal("expected an error when the decision log record fails, got nil")
+	}
+	if errors.Is(err, ErrCommitFailed) {
+		t.Fatalf("expected a decision log error, not ErrCommitFailed: %v", err)
+	}
+
+	for i, p := range participants {
+		if p.State() != StateRolledBack {
+			t.Fatalf("participant %d: expected StateRolledBack, got %s", i, p.State())
+		}
+	}
+}
@@ -12,6 +12,7 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/gosom/gosql2pc"
+	"github.com/gosom/gosql2pc/pgxa"
 	_ "github.com/jackc/pgx/stdlib"
 )
 
@@ -179,13 +180,13 @@ func concurrency2(userdb, orderdb *sql.DB) error {
 
 	go func() {
 		defer wg.Done()
-		userParticipant := gosql2pc.NewParticipant(userdb, func(ctx context.Context, tx *sql.Tx) error {
+		userParticipant := pgxa.NewParticipant(userdb, "userdb", func(ctx context.Context, tx *sql.Tx) error {
 			_, err := tx.ExecContext(ctx, "update users set id = $1 where id = $2", uuid1, userID)
 			time.Sleep(1 * time.Second) // delay the update
 			return err
 		})
 		// Prepare the second participant in the transaction
-		orderParticipant := gosql2pc.NewParticipant(orderdb, func(ctx context.Context, tx *sql.Tx) error {
+		orderParticipant := pgxa.NewParticipant(orderdb, "orderdb", func(ctx context.Context, tx *sql.Tx) error {
 			_, err := tx.ExecContext(ctx, "update orders set user_id = $1 where user_id = $2", uuid1, userID)
 			time.Sleep(5 * time.Second) // artificial delay here
 			return err
@@ -210,12 +211,12 @@ func concurrency2(userdb, orderdb *sql.DB) error {
 
 	go func() {
 		defer wg.Done()
-		userParticipant := gosql2pc.NewParticipant(userdb, func(ctx context.Context, tx *sql.Tx) error {
+		userParticipant := pgxa.NewParticipant(userdb, "userdb", func(ctx context.Context, tx *sql.Tx) error {
 			_, err := tx.ExecContext(ctx, "update users set id = $1 where id = $2", uuid2, userID)
 			return err
 		})
 		// Prepare the second participant in the transaction
-		orderParticipant := gosql2pc.NewParticipant(orderdb, func(ctx context.Context, tx *sql.Tx) error {
+		orderParticipant := pgxa.NewParticipant(orderdb, "orderdb", func(ctx context.Context, tx *sql.Tx) error {
 			_, err := tx.ExecContext(ctx, "update orders set user_id = $1 where user_id = $2", uuid2, userID)
 			return err
 		})
@@ -325,13 +326,13 @@ func setup() (*sql.DB, *sql.DB, error) {
 // insertUserAndOrder inserts a new user and order in a single distributed transaction using the two phase commit protocol
 func insertUserAndOrder(userdb, orderdb *sql.DB, userID, name, orderID string, amount int) error {
 	// Prepare the first participant in the transaction
-	userParticipant := gosql2pc.NewParticipant(userdb, func(ctx context.Context, tx *sql.Tx) error {
+	userParticipant := pgxa.NewParticipant(userdb, "userdb", func(ctx context.Context, tx *sql.Tx) error {
 		_, err := tx.ExecContext(ctx, "INSERT INTO users (id, name) VALUES ($1, $2)", userID, name)
 		return err
 	})
 
 	// Prepare the second participant in the transaction
-	orderParticipant := gosql2pc.NewParticipant(orderdb, func(ctx context.Context, tx *sql.Tx) error {
+	orderParticipant := pgxa.NewParticipant(orderdb, "orderdb", func(ctx context.Context, tx *sql.Tx) error {
 		_, err := tx.ExecContext(ctx, "INSERT INTO orders (id, user_id, amount) VALUES ($1, $2, $3)", orderID, userID, amount)
 		return err
 	})
@@ -0,0 +1,63 @@
+package gosql2pc
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+type gidContextKey struct{}
+
+// WithGID returns a context carrying gid, the GID a Participant
+// implementation must prepare under. Do sets this once per participant
+// before calling Prepare.
+func WithGID(ctx context.Context, gid string) context.Context {
+	return context.WithValue(ctx, gidContextKey{}, gid)
+}
+
+// GIDFromContext returns the GID set by WithGID. If none was set, e.g.
+// because a Participant is being exercised directly instead of through Do,
+// it generates a fresh random one instead.
+func GIDFromContext(ctx context.Context) string {
+	if gid, ok := ctx.Value(gidContextKey{}).(string); ok && gid != "" {
+		return gid
+	}
+	return defaultPrefix + uuid.New().String()
+}
+
+// newTxID returns a new unique identifier for a distributed transaction.
+func newTxID() string {
+	return uuid.New().String()
+}
+
+// gidFor returns the prepared-transaction GID used for the i-th participant
+// of the distributed transaction identified by txid. Embedding txid in every
+// participant GID lets a Recoverer map a dangling prepared transaction back
+// to the DecisionLog entry for the whole distributed transaction.
+func gidFor(txid string, i int) string {
+	return fmt.Sprintf("%s%s-%d", defaultPrefix, txid, i)
+}
+
+// txidFromGid extracts the distributed transaction id embedded in gid by
+// gidFor. It returns false if gid was not produced by this package.
+func txidFromGid(gid string) (string, bool) {
+	rest := strings.TrimPrefix(gid, defaultPrefix)
+	if rest == gid {
+		return "", false
+	}
+	idx := strings.LastIndex(rest, "-")
+	if idx < 0 {
+		return "", false
+	}
+	return rest[:idx], true
+}
+
+// TxIDFromGID extracts the distributed transaction id embedded in gid by Do.
+// It returns false if gid was not produced by this package. Driver-specific
+// recovery tooling outside this package, e.g. mysqlxa.Recoverer, uses it to
+// map a dangling prepared transaction back to a DecisionLog entry.
+func TxIDFromGID(gid string) (string, bool) {
+	return txidFromGid(gid)
+}
@@ -0,0 +1,52 @@
+package gosql2pc
+
+import "time"
+
+// Hooks lets callers observe each phase of a distributed transaction as it
+// runs, e.g. to emit metrics or structured logs per participant. Every
+// callback is optional; leave a field nil to skip it.
+type Hooks struct {
+	// BeforePrepare is called right before a participant's Prepare.
+	BeforePrepare func(index int, gid string)
+	// AfterPrepare is called right after a participant's Prepare returns,
+	// with how long it took and the error it returned, if any.
+	AfterPrepare func(index int, gid string, dur time.Duration, err error)
+	// BeforeCommit is called right before a participant's Commit.
+	BeforeCommit func(index int, gid string)
+	// AfterCommit is called right after a participant's Commit returns, with
+	// how long it took and the error it returned, if any.
+	AfterCommit func(index int, gid string, dur time.Duration, err error)
+	// OnRollback is called right after a participant's Rollback returns,
+	// with how long it took and the error it returned, if any.
+	OnRollback func(index int, gid string, dur time.Duration, err error)
+}
+
+func (h Hooks) beforePrepare(index int, gid string) {
+	if h.BeforePrepare != nil {
+		h.BeforePrepare(index, gid)
+	}
+}
+
+func (h Hooks) afterPrepare(index int, gid string, dur time.Duration, err error) {
+	if h.AfterPrepare != nil {
+		h.AfterPrepare(index, gid, dur, err)
+	}
+}
+
+func (h Hooks) beforeCommit(index int, gid string) {
+	if h.BeforeCommit != nil {
+		h.BeforeCommit(index, gid)
+	}
+}
+
+func (h Hooks) afterCommit(index int, gid string, dur time.Duration, err error) {
+	if h.AfterCommit != nil {
+		h.AfterCommit(index, gid, dur, err)
+	}
+}
+
+func (h Hooks) onRollback(index int, gid string, dur time.Duration, err error) {
+	if h.OnRollback != nil {
+		h.OnRollback(index, gid, dur, err)
+	}
+}
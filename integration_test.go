@@ -0,0 +1,77 @@
+package gosql2pc_test
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"testing"
+
+	_ "github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq"
+
+	"github.com/gosom/gosql2pc"
+	"github.com/gosom/gosql2pc/mysqlxa"
+	"github.com/gosom/gosql2pc/pgxa"
+)
+
+// TestDoMixedDrivers exercises a single distributed transaction spanning a
+// Postgres and a MySQL participant, verifying the two drivers' XA
+// translations compose correctly through the shared Participant interface.
+// Set both GOSQL2PC_PG_DSN and GOSQL2PC_MYSQL_DSN to run it; it is skipped
+// otherwise.
+func TestDoMixedDrivers(t *testing.T) {
+	pgDSN := os.Getenv("GOSQL2PC_PG_DSN")
+	mysqlDSN := os.Getenv("GOSQL2PC_MYSQL_DSN")
+	if pgDSN == "" || mysqlDSN == "" {
+		t.Skip("GOSQL2PC_PG_DSN and GOSQL2PC_MYSQL_DSN must both be set to run the mixed-driver integration test")
+	}
+
+	pgDB, err := sql.Open("postgres", pgDSN)
+	if err != nil {
+		t.Fatalf("open postgres: %s", err)
+	}
+	t.Cleanup(func() { pgDB.Close() })
+
+	mysqlDB, err := sql.Open("mysql", mysqlDSN)
+	if err != nil {
+		t.Fatalf("open mysql: %s", err)
+	}
+	t.Cleanup(func() { mysqlDB.Close() })
+
+	ctx := context.Background()
+	if _, err := pgDB.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS gosql2pc_mixed_test (id INT PRIMARY KEY)"); err != nil {
+		t.Fatalf("create postgres table: %s", err)
+	}
+	t.Cleanup(func() { pgDB.ExecContext(context.Background(), "DROP TABLE IF EXISTS gosql2pc_mixed_test") })
+
+	if _, err := mysqlDB.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS gosql2pc_mixed_test (id INT PRIMARY KEY)"); err != nil {
+		t.Fatalf("create mysql table: %s", err)
+	}
+	t.Cleanup(func() { mysqlDB.ExecContext(context.Background(), "DROP TABLE IF EXISTS gosql2pc_mixed_test") })
+
+	pgParticipant := pgxa.NewParticipant(pgDB, "postgres", func(ctx context.Context, tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, "INSERT INTO gosql2pc_mixed_test (id) VALUES (1)")
+		return err
+	})
+	mysqlParticipant := mysqlxa.NewParticipant(mysqlDB, "mysql", func(ctx context.Context, conn *sql.Conn) error {
+		_, err := conn.ExecContext(ctx, "INSERT INTO gosql2pc_mixed_test (id) VALUES (1)")
+		return err
+	})
+
+	err = gosql2pc.Do(ctx, gosql2pc.Params{
+		Participants: []gosql2pc.Participant{pgParticipant, mysqlParticipant},
+	})
+	if err != nil {
+		t.Fatalf("Do: %s", err)
+	}
+
+	for name, db := range map[string]*sql.DB{"postgres": pgDB, "mysql": mysqlDB} {
+		var count int
+		if err := db.QueryRowContext(ctx, "SELECT count(*) FROM gosql2pc_mixed_test WHERE id = 1").Scan(&count); err != nil {
+			t.Fatalf("%s: query: %s", name, err)
+		}
+		if count != 1 {
+			t.Fatalf("%s: expected committed row, count=%d", name, count)
+		}
+	}
+}
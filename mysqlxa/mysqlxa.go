@@ -0,0 +1,118 @@
+// Package mysqlxa implements a gosql2pc.Participant backed by MySQL's XA
+// transaction statements (XA START / XA END / XA PREPARE / XA COMMIT / XA
+// ROLLBACK), MySQL's equivalent of PostgreSQL's PREPARE TRANSACTION.
+package mysqlxa
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/gosom/gosql2pc"
+)
+
+// Participant is a gosql2pc.Participant backed by a MySQL database. Because
+// an XA transaction is tied to the connection that started it, do operates
+// directly on a *sql.Conn rather than a *sql.Tx: transaction boundaries are
+// controlled by the XA statements, not by BEGIN/COMMIT. Once prepared, the
+// XA transaction is durable server-side, so Commit and Rollback run on any
+// connection from the pool rather than the one Prepare used.
+type Participant struct {
+	do func(ctx context.Context, conn *sql.Conn) error
+
+	db    *sql.DB
+	dbID  string
+	gid   string
+	state gosql2pc.State
+}
+
+// NewParticipant creates a new MySQL XA participant. dbID identifies the
+// database for tracing, e.g. "orders-db"; it may be left empty.
+// The do function is called between XA START and XA END. It should contain
+// all the database operations that should be performed in the transaction.
+func NewParticipant(db *sql.DB, dbID string, do func(ctx context.Context, conn *sql.Conn) error) *Participant {
+	return &Participant{
+		db:   db,
+		dbID: dbID,
+		do:   do,
+	}
+}
+
+// Prepare implements gosql2pc.Participant.
+func (o *Participant) Prepare(ctx context.Context) error {
+	o.state = gosql2pc.StateInitial
+
+	conn, err := o.db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	gid := gosql2pc.GIDFromContext(ctx)
+	xid := hexXID(gid)
+
+	if _, err := conn.ExecContext(ctx, fmt.Sprintf("XA START %s", xid)); err != nil {
+		return err
+	}
+	if err := o.do(ctx, conn); err != nil {
+		return err
+	}
+	if _, err := conn.ExecContext(ctx, fmt.Sprintf("XA END %s", xid)); err != nil {
+		return err
+	}
+	if _, err := conn.ExecContext(ctx, fmt.Sprintf("XA PREPARE %s", xid)); err != nil {
+		return err
+	}
+
+	o.gid = gid
+	o.state = gosql2pc.StatePrepared
+	return nil
+}
+
+// Commit implements gosql2pc.Participant.
+func (o *Participant) Commit() error {
+	o.state = gosql2pc.StateCommitting
+	if _, err := o.db.ExecContext(context.Background(), fmt.Sprintf("XA COMMIT %s", hexXID(o.gid))); err != nil {
+		// the commit statement errored, but whether it landed on the server
+		// before the error is ambiguous, so this participant must only ever
+		// be retried, never blindly rolled back
+		o.state = gosql2pc.StateCommitFailed
+		return err
+	}
+	o.state = gosql2pc.StateCommitted
+	return nil
+}
+
+// Rollback implements gosql2pc.Participant.
+func (o *Participant) Rollback() error {
+	if o.state != gosql2pc.StatePrepared && o.state != gosql2pc.StateCommitFailed {
+		return nil
+	}
+	if _, err := o.db.ExecContext(context.Background(), fmt.Sprintf("XA ROLLBACK %s", hexXID(o.gid))); err != nil {
+		return err
+	}
+	o.state = gosql2pc.StateRolledBack
+	return nil
+}
+
+// ID implements gosql2pc.Participant.
+func (o *Participant) ID() string {
+	return o.gid
+}
+
+// DBID implements gosql2pc.Participant.
+func (o *Participant) DBID() string {
+	return o.dbID
+}
+
+// State implements gosql2pc.Participant.
+func (o *Participant) State() gosql2pc.State {
+	return o.state
+}
+
+// hexXID formats gid as a hex-quoted XA xid. MySQL rejects arbitrary UTF-8 in
+// a quoted xid, so the gid is passed as a hex literal instead, e.g.
+// XA START 0x676f73716c3270632d...
+func hexXID(gid string) string {
+	return fmt.Sprintf("0x%x", gid)
+}
@@ -0,0 +1,89 @@
+package mysqlxa
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"testing"
+
+	_ "github.com/go-sql-driver/mysql"
+
+	"github.com/gosom/gosql2pc"
+)
+
+// These are integration tests against a real MySQL instance with XA
+// transactions enabled. Set GOSQL2PC_MYSQL_DSN to a DSN the mysql driver
+// accepts to run them; they are skipped otherwise.
+func testDB(t *testing.T) *sql.DB {
+	t.Helper()
+	dsn := os.Getenv("GOSQL2PC_MYSQL_DSN")
+	if dsn == "" {
+		t.Skip("GOSQL2PC_MYSQL_DSN not set, skipping mysqlxa integration test")
+	}
+	db, err := sql.Open("mysql", dsn)
+	if err != nil {
+		t.Fatalf("open: %s", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestParticipantCommit(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+
+	if _, err := db.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS gosql2pc_mysqlxa_test (id INT PRIMARY KEY)"); err != nil {
+		t.Fatalf("create table: %s", err)
+	}
+	t.Cleanup(func() {
+		db.ExecContext(context.Background(), "DROP TABLE IF EXISTS gosql2pc_mysqlxa_test")
+	})
+
+	p := NewParticipant(db, "mysqlxa-test-db", func(ctx context.Context, conn *sql.Conn) error {
+		_, err := conn.ExecContext(ctx, "INSERT INTO gosql2pc_mysqlxa_test (id) VALUES (1)")
+		return err
+	})
+
+	if err := gosql2pc.Do(ctx, gosql2pc.Params{Participants: []gosql2pc.Participant{p}}); err != nil {
+		t.Fatalf("Do: %s", err)
+	}
+	if p.State() != gosql2pc.StateCommitted {
+		t.Fatalf("expected StateCommitted, got %s", p.State())
+	}
+
+	var count int
+	if err := db.QueryRowContext(ctx, "SELECT count(*) FROM gosql2pc_mysqlxa_test WHERE id = 1").Scan(&count); err != nil {
+		t.Fatalf("query: %s", err)
+	}
+	if count != 1 {
+		t.Fatalf("expected row to be committed, count=%d", count)
+	}
+}
+
+func TestParticipantPrepareFailureLeavesNothingPrepared(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+
+	wantErr := errors.New("boom")
+	p := NewParticipant(db, "mysqlxa-test-db", func(ctx context.Context, conn *sql.Conn) error {
+		return wantErr
+	})
+
+	err := p.Prepare(gosql2pc.WithGID(ctx, "gosql2pc-test-mysqlxa-rollback"))
+	if !errors.Is(err, wantErr) {
+		t.Fatalf("expected %v, got %v", wantErr, err)
+	}
+	if p.State() != gosql2pc.StateInitial {
+		t.Fatalf("expected StateInitial after a failed Prepare, got %s", p.State())
+	}
+
+	rows, err := db.QueryContext(ctx, "XA RECOVER")
+	if err != nil {
+		t.Fatalf("XA RECOVER: %s", err)
+	}
+	defer rows.Close()
+	if rows.Next() {
+		t.Fatalf("expected no dangling XA transaction after a failed Prepare")
+	}
+}
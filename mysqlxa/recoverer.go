@@ -0,0 +1,102 @@
+package mysqlxa
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/gosom/gosql2pc"
+)
+
+// Recoverer scans a MySQL database's XA RECOVER output for dangling prepared
+// transactions whose gtrid carries a gosql2pc GID and resolves them against
+// a gosql2pc.DecisionLog. It is the mysqlxa counterpart of gosql2pc.Recoverer,
+// which only understands PostgreSQL's pg_prepared_xacts, so a distributed
+// transaction with participants of both kinds needs both Recoverers run.
+//
+// Unlike gosql2pc.Recoverer, Recoverer has no Timeout: MySQL's XA RECOVER
+// does not report when a transaction was prepared, so a dangling transaction
+// with no recorded decision is left prepared rather than guessed at; it is
+// resolved once a decision is recorded for it on a later run.
+type Recoverer struct {
+	// LogFn is used for logging. Leave empty for no logging.
+	LogFn func(msg string, args ...any)
+}
+
+type danglingXID struct {
+	gid string
+}
+
+// Run scans each of dbs for prepared XA transactions whose gtrid was
+// produced by gosql2pc and resolves them against log: any recorded
+// decision, DecisionCommit or DecisionDone, means the transaction commits.
+func (r Recoverer) Run(ctx context.Context, dbs []*sql.DB, log gosql2pc.DecisionLog) error {
+	logFn := r.LogFn
+	if logFn == nil {
+		logFn = func(string, ...any) {}
+	}
+
+	for _, db := range dbs {
+		xids, err := danglingXIDs(ctx, db)
+		if err != nil {
+			return err
+		}
+		for _, x := range xids {
+			if err := r.resolve(ctx, db, log, x); err != nil {
+				logFn("mysqlxa recoverer: failed to resolve %s: %s", x.gid, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (r Recoverer) resolve(ctx context.Context, db *sql.DB, log gosql2pc.DecisionLog, x danglingXID) error {
+	txid, ok := gosql2pc.TxIDFromGID(x.gid)
+	if !ok {
+		return nil
+	}
+
+	_, err := log.Lookup(txid)
+	switch {
+	case err == nil:
+		// any recorded decision, DecisionCommit or DecisionDone, means the
+		// coordinator's decision was to commit.
+		_, err := db.ExecContext(ctx, fmt.Sprintf("XA COMMIT %s", hexXID(x.gid)))
+		return err
+	case errors.Is(err, gosql2pc.ErrDecisionNotFound):
+		// no decision recorded yet: leave it prepared rather than guess,
+		// since XA RECOVER gives us no age to compare against a timeout.
+		return nil
+	default:
+		return err
+	}
+}
+
+// danglingXIDs returns the prepared XA transactions in db's XA RECOVER
+// output whose gtrid carries the gosql2pc prefix.
+func danglingXIDs(ctx context.Context, db *sql.DB) ([]danglingXID, error) {
+	rows, err := db.QueryContext(ctx, "XA RECOVER")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []danglingXID
+	for rows.Next() {
+		var formatID, gtridLength, bqualLength int
+		var data []byte
+		if err := rows.Scan(&formatID, &gtridLength, &bqualLength, &data); err != nil {
+			return nil, err
+		}
+		if gtridLength < 0 || gtridLength > len(data) {
+			continue
+		}
+		gid := string(data[:gtridLength])
+		if _, ok := gosql2pc.TxIDFromGID(gid); !ok {
+			continue
+		}
+		out = append(out, danglingXID{gid: gid})
+	}
+	return out, rows.Err()
+}
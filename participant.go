@@ -1,70 +1,78 @@
 package gosql2pc
 
-import (
-	"context"
-	"database/sql"
-	"fmt"
+import "context"
 
-	"github.com/google/uuid"
-)
-
-// Participant is a participant in a 2PC transaction
-type Participant struct {
-	do func(ctx context.Context, tx *sql.Tx) error
-
-	db         *sql.DB
-	txid       string
-	prepared   bool
-	committed  bool
-	rollbacked bool
-}
-
-// NewParticipant creates a new participant
-// The do function is called when the participant is prepared. It should contain all the
-// database operations that should be performed in the transaction.
-func NewParticipant(db *sql.DB, do func(ctx context.Context, tx *sql.Tx) error) Participant {
-	return Participant{
-		db: db,
-		do: do,
-	}
+// Participant is a participant in a 2PC transaction. Driver-specific
+// implementations live in subpackages: gosql2pc/pgxa for PostgreSQL, backed
+// by PREPARE TRANSACTION/COMMIT PREPARED, and gosql2pc/mysqlxa for MySQL,
+// backed by the XA START/END/PREPARE/COMMIT/ROLLBACK statements. Because Do
+// only depends on this interface, a single distributed transaction can mix
+// participants from different drivers, e.g. a Postgres and a MySQL shard.
+type Participant interface {
+	// Prepare runs the participant's statements and votes to commit. Do
+	// assigns the GID the participant must prepare under via WithGID;
+	// implementations retrieve it with GIDFromContext.
+	Prepare(ctx context.Context) error
+	// Commit durably commits a previously prepared participant.
+	Commit() error
+	// Rollback undoes a previously prepared participant. It must be a no-op
+	// unless State is Prepared or CommitFailed.
+	Rollback() error
+	// ID returns the GID the participant prepared under, or the empty
+	// string if Prepare has not been called yet.
+	ID() string
+	// DBID identifies the database or shard the participant talks to, e.g.
+	// "orders-db" or a DSN host:port. Do attaches it to prepare/commit/
+	// rollback spans so an operator can correlate a hung phase with locked
+	// rows on a specific shard. Implementations may return "" if they have
+	// no meaningful identifier to report.
+	DBID() string
+	// State returns the participant's current position in its two-phase
+	// lifecycle. Do's finalizer uses it to decide whether a participant left
+	// over from a failed attempt needs to be rolled back or, once a commit
+	// decision has been made, retried rather than rolled back.
+	State() State
 }
 
-func (o *Participant) prepare(ctx context.Context) error {
-	tx, err := o.db.BeginTx(ctx, nil)
-	if err != nil {
-		return err
-	}
-	defer tx.Rollback()
-	if err := o.do(ctx, tx); err != nil {
-		return err
-	}
-	o.txid = getPreparedGid()
-	if _, err := tx.ExecContext(ctx, fmt.Sprintf("PREPARE TRANSACTION '%s'", o.txid)); err != nil {
-		return err
-	}
-	o.prepared = true
-	return nil
-}
+// State is a Participant's position in the two-phase commit lifecycle.
+type State int
 
-func (o *Participant) rollback() error {
-	if o.txid == "" || o.rollbacked || o.committed {
-		return nil
-	}
-	if _, err := o.db.Exec(fmt.Sprintf("ROLLBACK PREPARED '%s'", o.txid)); err != nil {
-		return err
-	}
-	o.rollbacked = true
-	return nil
-}
+const (
+	// StateInitial is a Participant that has not been prepared yet.
+	StateInitial State = iota
+	// StatePrepared is a Participant that voted to commit and is holding a
+	// prepared transaction open on its database.
+	StatePrepared
+	// StateCommitting is a Participant whose Commit is in flight. A
+	// Participant implementation should not be observed in this state once
+	// Commit has returned, successfully or not.
+	StateCommitting
+	// StateCommitted is a Participant whose commit has durably succeeded.
+	StateCommitted
+	// StateCommitFailed is a Participant whose Commit call returned an
+	// error. Its state on the database is ambiguous: the commit may have
+	// landed or not, so it must never be rolled back blindly, only retried.
+	StateCommitFailed
+	// StateRolledBack is a Participant that was rolled back.
+	StateRolledBack
+)
 
-func (o *Participant) commit() error {
-	if _, err := o.db.Exec(fmt.Sprintf("COMMIT PREPARED '%s'", o.txid)); err != nil {
-		return err
+// String implements fmt.Stringer.
+func (s State) String() string {
+	switch s {
+	case StateInitial:
+		return "initial"
+	case StatePrepared:
+		return "prepared"
+	case StateCommitting:
+		return "committing"
+	case StateCommitted:
+		return "committed"
+	case StateCommitFailed:
+		return "commitFailed"
+	case StateRolledBack:
+		return "rolledBack"
+	default:
+		return "unknown"
 	}
-	o.committed = true
-	return nil
-}
-
-func getPreparedGid() string {
-	return defaultPrefix + uuid.New().String()
 }
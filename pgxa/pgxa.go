@@ -0,0 +1,95 @@
+// Package pgxa implements a gosql2pc.Participant backed by PostgreSQL's
+// two-phase commit support (PREPARE TRANSACTION / COMMIT PREPARED / ROLLBACK
+// PREPARED).
+package pgxa
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/gosom/gosql2pc"
+)
+
+// Participant is a gosql2pc.Participant backed by a PostgreSQL database.
+type Participant struct {
+	do func(ctx context.Context, tx *sql.Tx) error
+
+	db    *sql.DB
+	dbID  string
+	gid   string
+	state gosql2pc.State
+}
+
+// NewParticipant creates a new Postgres participant. dbID identifies the
+// database for tracing, e.g. "orders-db"; it may be left empty.
+// The do function is called when the participant is prepared. It should contain all the
+// database operations that should be performed in the transaction.
+func NewParticipant(db *sql.DB, dbID string, do func(ctx context.Context, tx *sql.Tx) error) *Participant {
+	return &Participant{
+		db:   db,
+		dbID: dbID,
+		do:   do,
+	}
+}
+
+// Prepare implements gosql2pc.Participant.
+func (o *Participant) Prepare(ctx context.Context) error {
+	o.state = gosql2pc.StateInitial
+
+	tx, err := o.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := o.do(ctx, tx); err != nil {
+		return err
+	}
+	o.gid = gosql2pc.GIDFromContext(ctx)
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("PREPARE TRANSACTION '%s'", o.gid)); err != nil {
+		return err
+	}
+	o.state = gosql2pc.StatePrepared
+	return nil
+}
+
+// Commit implements gosql2pc.Participant.
+func (o *Participant) Commit() error {
+	o.state = gosql2pc.StateCommitting
+	if _, err := o.db.Exec(fmt.Sprintf("COMMIT PREPARED '%s'", o.gid)); err != nil {
+		// the commit statement errored, but whether it landed on the server
+		// before the error is ambiguous, so this participant must only ever
+		// be retried, never blindly rolled back
+		o.state = gosql2pc.StateCommitFailed
+		return err
+	}
+	o.state = gosql2pc.StateCommitted
+	return nil
+}
+
+// Rollback implements gosql2pc.Participant.
+func (o *Participant) Rollback() error {
+	if o.state != gosql2pc.StatePrepared && o.state != gosql2pc.StateCommitFailed {
+		return nil
+	}
+	if _, err := o.db.Exec(fmt.Sprintf("ROLLBACK PREPARED '%s'", o.gid)); err != nil {
+		return err
+	}
+	o.state = gosql2pc.StateRolledBack
+	return nil
+}
+
+// ID implements gosql2pc.Participant.
+func (o *Participant) ID() string {
+	return o.gid
+}
+
+// DBID implements gosql2pc.Participant.
+func (o *Participant) DBID() string {
+	return o.dbID
+}
+
+// State implements gosql2pc.Participant.
+func (o *Participant) State() gosql2pc.State {
+	return o.state
+}
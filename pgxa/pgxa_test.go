@@ -0,0 +1,89 @@
+package pgxa
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"testing"
+
+	_ "github.com/lib/pq"
+
+	"github.com/gosom/gosql2pc"
+)
+
+// These are integration tests against a real PostgreSQL instance. Set
+// GOSQL2PC_PG_DSN to a DSN lib/pq accepts to run them; they are skipped
+// otherwise.
+func testDB(t *testing.T) *sql.DB {
+	t.Helper()
+	dsn := os.Getenv("GOSQL2PC_PG_DSN")
+	if dsn == "" {
+		t.Skip("GOSQL2PC_PG_DSN not set, skipping pgxa integration test")
+	}
+	db, err := sql.Open("postgres", dsn)
+	if err != nil {
+		t.Fatalf("open: %s", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestParticipantCommit(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+
+	if _, err := db.ExecContext(ctx, "CREATE TABLE IF NOT EXISTS gosql2pc_pgxa_test (id INT PRIMARY KEY)"); err != nil {
+		t.Fatalf("create table: %s", err)
+	}
+	t.Cleanup(func() {
+		db.ExecContext(context.Background(), "DROP TABLE IF EXISTS gosql2pc_pgxa_test")
+	})
+
+	p := NewParticipant(db, "pgxa-test-db", func(ctx context.Context, tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, "INSERT INTO gosql2pc_pgxa_test (id) VALUES (1)")
+		return err
+	})
+
+	if err := gosql2pc.Do(ctx, gosql2pc.Params{Participants: []gosql2pc.Participant{p}}); err != nil {
+		t.Fatalf("Do: %s", err)
+	}
+	if p.State() != gosql2pc.StateCommitted {
+		t.Fatalf("expected StateCommitted, got %s", p.State())
+	}
+
+	var count int
+	if err := db.QueryRowContext(ctx, "SELECT count(*) FROM gosql2pc_pgxa_test WHERE id = 1").Scan(&count); err != nil {
+		t.Fatalf("query: %s", err)
+	}
+	if count != 1 {
+		t.Fatalf("expected row to be committed, count=%d", count)
+	}
+}
+
+func TestParticipantPrepareFailureLeavesNothingPrepared(t *testing.T) {
+	db := testDB(t)
+	ctx := context.Background()
+
+	wantErr := errors.New("boom")
+	p := NewParticipant(db, "pgxa-test-db", func(ctx context.Context, tx *sql.Tx) error {
+		return wantErr
+	})
+
+	err := p.Prepare(gosql2pc.WithGID(ctx, "gosql2pc-test-pgxa-rollback"))
+	if !errors.Is(err, wantErr) {
+		t.Fatalf("expected %v, got %v", wantErr, err)
+	}
+	if p.State() != gosql2pc.StateInitial {
+		t.Fatalf("expected StateInitial after a failed Prepare, got %s", p.State())
+	}
+
+	var count int
+	if err := db.QueryRowContext(ctx,
+		"SELECT count(*) FROM pg_prepared_xacts WHERE gid = 'gosql2pc-test-pgxa-rollback'").Scan(&count); err != nil {
+		t.Fatalf("query pg_prepared_xacts: %s", err)
+	}
+	if count != 0 {
+		t.Fatalf("expected no dangling prepared transaction, found %d", count)
+	}
+}
@@ -0,0 +1,108 @@
+package gosql2pc
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Recoverer scans PostgreSQL participant databases for prepared transactions
+// left behind by a crashed coordinator and resolves them using a
+// DecisionLog. It queries pg_prepared_xacts, so it only sees pgxa
+// participants; a distributed transaction that also has mysqlxa
+// participants needs mysqlxa.Recoverer run against its MySQL databases too.
+//
+// If no DecisionLog entry exists for a dangling prepared transaction, it is
+// rolled back once it has been prepared for longer than Timeout. This means
+// that running a Recoverer without ever configuring a DecisionLog on Do is
+// still safe: stale prepared transactions are eventually rolled back instead
+// of locking rows forever, they just cannot be committed.
+type Recoverer struct {
+	// Timeout is how long a dangling prepared transaction with no recorded
+	// decision is left alone before it is rolled back. Defaults to 1 hour.
+	Timeout time.Duration
+	// LogFn is used for logging. Leave empty for no logging.
+	LogFn func(msg string, args ...any)
+}
+
+type danglingGid struct {
+	gid        string
+	preparedAt time.Time
+}
+
+// Run scans each of dbs for prepared transactions whose GID was produced by
+// this package and resolves them against log: if any decision is recorded
+// for the transaction, whether DecisionCommit or DecisionDone, it is
+// committed; otherwise, once it is older than r.Timeout, it is rolled back.
+func (r Recoverer) Run(ctx context.Context, dbs []*sql.DB, log DecisionLog) error {
+	logFn := r.LogFn
+	if logFn == nil {
+		logFn = defaultLog
+	}
+	timeout := r.Timeout
+	if timeout <= 0 {
+		timeout = time.Hour
+	}
+
+	for _, db := range dbs {
+		gids, err := danglingGids(ctx, db)
+		if err != nil {
+			return err
+		}
+		for _, g := range gids {
+			if err := r.resolve(ctx, db, log, g, timeout); err != nil {
+				logFn("recoverer: failed to resolve %s: %s", g.gid, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (r Recoverer) resolve(ctx context.Context, db *sql.DB, log DecisionLog, g danglingGid, timeout time.Duration) error {
+	txid, ok := txidFromGid(g.gid)
+	if !ok {
+		return nil
+	}
+
+	_, err := log.Lookup(txid)
+	switch {
+	case err == nil:
+		// any recorded decision, DecisionCommit or DecisionDone, means the
+		// coordinator's decision was to commit: DecisionDone is only ever
+		// written after every participant, including this one's siblings,
+		// has already committed. Rolling back here would diverge from them.
+		_, err := db.ExecContext(ctx, fmt.Sprintf("COMMIT PREPARED '%s'", g.gid))
+		return err
+	case errors.Is(err, ErrDecisionNotFound):
+		if time.Since(g.preparedAt) < timeout {
+			return nil
+		}
+		_, err := db.ExecContext(ctx, fmt.Sprintf("ROLLBACK PREPARED '%s'", g.gid))
+		return err
+	default:
+		return err
+	}
+}
+
+// danglingGids returns the prepared transactions in db's pg_prepared_xacts
+// view whose GID carries the gosql2pc prefix.
+func danglingGids(ctx context.Context, db *sql.DB) ([]danglingGid, error) {
+	rows, err := db.QueryContext(ctx,
+		"SELECT gid, prepared FROM pg_prepared_xacts WHERE gid LIKE $1", defaultPrefix+"%")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []danglingGid
+	for rows.Next() {
+		var g danglingGid
+		if err := rows.Scan(&g.gid, &g.preparedAt); err != nil {
+			return nil, err
+		}
+		out = append(out, g)
+	}
+	return out, rows.Err()
+}
@@ -0,0 +1,91 @@
+package gosql2pc
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/lib/pq"
+)
+
+// RetryPolicy controls how many times, and how long to wait between, Do
+// retries an entire 2PC attempt after prepare fails with a transient error.
+type RetryPolicy struct {
+	// MaxAttempts is the total number of attempts, including the first.
+	// Leave zero, or one, to disable retries.
+	MaxAttempts int
+	// BaseDelay is the backoff delay before the second attempt. Each
+	// subsequent delay doubles, capped at MaxDelay.
+	BaseDelay time.Duration
+	// MaxDelay caps the backoff delay before Jitter is applied, so a jittered
+	// delay can exceed it by up to the Jitter fraction. Zero means no cap.
+	MaxDelay time.Duration
+	// Jitter, between 0 and 1, randomizes each delay by up to that fraction
+	// in either direction.
+	Jitter float64
+}
+
+func (p RetryPolicy) maxAttempts() int {
+	if p.MaxAttempts < 1 {
+		return 1
+	}
+	return p.MaxAttempts
+}
+
+func (p RetryPolicy) delay(attempt int) time.Duration {
+	if p.BaseDelay <= 0 {
+		return 0
+	}
+	d := float64(p.BaseDelay) * math.Pow(2, float64(attempt-1))
+	if p.MaxDelay > 0 && d > float64(p.MaxDelay) {
+		d = float64(p.MaxDelay)
+	}
+	if p.Jitter > 0 {
+		d *= 1 - p.Jitter + rand.Float64()*2*p.Jitter
+	}
+	return time.Duration(d)
+}
+
+// Transient Postgres SQLSTATE codes that are safe to retry.
+const (
+	sqlstateSerializationFailure = "40001"
+	sqlstateDeadlockDetected     = "40P01"
+)
+
+// DefaultIsRetryable is the default Params.IsRetryable hook. It recognizes
+// Postgres serialization failures (40001) and deadlocks (40P01) reported by
+// either the pgx or lib/pq drivers, a reset connection, and a context
+// deadline that expired before the commit phase began.
+func DefaultIsRetryable(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return isRetryableSQLState(pgErr.Code)
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		return isRetryableSQLState(string(pqErr.Code))
+	}
+
+	return strings.Contains(err.Error(), "connection reset by peer")
+}
+
+func isRetryableSQLState(code string) bool {
+	switch code {
+	case sqlstateSerializationFailure, sqlstateDeadlockDetected:
+		return true
+	default:
+		return false
+	}
+}
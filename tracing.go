@@ -0,0 +1,50 @@
+package gosql2pc
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// startTransactionSpan opens the gosql2pc.transaction span that every phase
+// span below is linked to as a child, via normal OpenTelemetry context
+// propagation. It returns ctx unchanged, and a nil span, if tracer is nil.
+func startTransactionSpan(ctx context.Context, tracer trace.Tracer, txid string) (context.Context, trace.Span) {
+	if tracer == nil {
+		return ctx, nil
+	}
+	return tracer.Start(ctx, "gosql2pc.transaction", trace.WithAttributes(
+		attribute.String("gosql2pc.txid", txid),
+	))
+}
+
+// startPhaseSpan opens a child span for a single participant's prepare,
+// commit or rollback. name is one of "gosql2pc.prepare", "gosql2pc.commit"
+// or "gosql2pc.rollback". dbID, from Participant.DBID, identifies the
+// database or shard the participant talks to, so an operator can correlate a
+// hung phase with locked rows on a specific shard.
+func startPhaseSpan(ctx context.Context, tracer trace.Tracer, name string, index int, gid, dbID string) (context.Context, trace.Span) {
+	if tracer == nil {
+		return ctx, nil
+	}
+	return tracer.Start(ctx, name, trace.WithAttributes(
+		attribute.String("gosql2pc.gid", gid),
+		attribute.Int("gosql2pc.participant", index),
+		attribute.String("gosql2pc.db", dbID),
+	))
+}
+
+// endSpan records err on span, if any, and ends it. It is a no-op if span is
+// nil, i.e. no Tracer was configured.
+func endSpan(span trace.Span, err error) {
+	if span == nil {
+		return
+	}
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}